@@ -0,0 +1,56 @@
+package types // import "github.com/docker/docker/api/types"
+
+import "time"
+
+// ContainerLogsOptions holds parameters to filter logs with.
+type ContainerLogsOptions struct {
+	ShowStdout bool
+	ShowStderr bool
+	Since      string
+	Until      string
+	Timestamps bool
+	Follow     bool
+	Tail       string
+	Details    bool
+
+	// InactivityTimeout, when set, causes the log stream to be aborted with
+	// ErrInactivityTimeout if no data is read from it for the given
+	// duration. It applies whether or not Follow is set, though it is mainly
+	// useful with Follow.
+	InactivityTimeout time.Duration
+
+	// SinceTime and UntilTime, when non-zero, take precedence over Since and
+	// Until respectively and are serialized directly without going through
+	// the ambiguous RFC3339-or-Unix-seconds string parsing that Since/Until
+	// require.
+	SinceTime time.Time
+	UntilTime time.Time
+
+	// SinceDuration and UntilDuration, when non-zero, take precedence over
+	// SinceTime/Since and UntilTime/Until respectively. They are resolved
+	// to an absolute time relative to when the request is made, as
+	// time.Now().Add(-d).
+	SinceDuration time.Duration
+	UntilDuration time.Duration
+
+	// Format selects the wire format of the returned log stream. It
+	// defaults to ContainerLogsFormatRaw, the existing plain/multiplexed
+	// format described on ContainerLogs.
+	Format ContainerLogsFormat
+}
+
+// ContainerLogsFormat selects the format ContainerLogs/ContainerLogsString
+// return their stream in.
+type ContainerLogsFormat string
+
+const (
+	// ContainerLogsFormatRaw is the default: the plain stream for TTY
+	// containers, or the stdcopy-multiplexed stream otherwise.
+	ContainerLogsFormatRaw ContainerLogsFormat = ""
+
+	// ContainerLogsFormatJSONLines returns one JSON object per log line,
+	// of the form {"stream":"stdout","time":"...","attrs":{...},"message":"..."},
+	// with stdout/stderr already demultiplexed and Timestamps/Details (if
+	// requested) parsed out into structured fields.
+	ContainerLogsFormatJSONLines ContainerLogsFormat = "json-lines"
+)
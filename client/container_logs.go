@@ -1,15 +1,18 @@
 package client // import "github.com/docker/docker/client"
 
 import (
+	"bufio"
 	"bytes"
 	"context"
-	"github.com/docker/docker/pkg/stdcopy"
 	"io"
 	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/pkg/errors"
 )
 
@@ -34,8 +37,38 @@ import (
 // This is the size of OUTPUT.
 //
 // You can use github.com/docker/docker/pkg/stdcopy.StdCopy to demultiplex this
-// stream.
+// stream, or ContainerLogsStreams/ContainerLogsLines below if you'd rather not
+// deal with the frame format yourself.
+//
+// If options.InactivityTimeout is set, the returned reader aborts with
+// ErrInactivityTimeout once that long passes without a successful Read. This
+// is mainly useful together with Follow, to detect a stream that has gone
+// quiet without the caller having to run its own watchdog goroutine.
+//
+// Since/Until accept an RFC3339 or Unix timestamp string, which is ambiguous
+// enough to get wrong; SinceTime/UntilTime and SinceDuration/UntilDuration
+// let callers pass a time.Time or time.Duration directly instead, and take
+// precedence over the string fields when set.
+//
+// Setting options.Format to ContainerLogsFormatJSONLines changes the
+// returned stream to one JSON object per log line instead; see
+// ContainerLogsFormatJSONLines for its shape.
 func (cli *Client) ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	body, err := cli.containerLogsRaw(ctx, container, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Format != types.ContainerLogsFormatJSONLines {
+		return body, nil
+	}
+	return cli.containerLogsJSONLines(ctx, container, body, options)
+}
+
+// containerLogsRaw performs the actual logs request and returns the
+// response body as-is: the plain or stdcopy-multiplexed stream described on
+// ContainerLogs, regardless of options.Format.
+func (cli *Client) containerLogsRaw(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
 	query := url.Values{}
 	if options.ShowStdout {
 		query.Set("stdout", "1")
@@ -45,7 +78,12 @@ func (cli *Client) ContainerLogs(ctx context.Context, container string, options
 		query.Set("stderr", "1")
 	}
 
-	if options.Since != "" {
+	switch {
+	case options.SinceDuration != 0:
+		query.Set("since", timetypes.Timestamp(time.Now().Add(-options.SinceDuration)))
+	case !options.SinceTime.IsZero():
+		query.Set("since", timetypes.Timestamp(options.SinceTime))
+	case options.Since != "":
 		ts, err := timetypes.GetTimestamp(options.Since, time.Now())
 		if err != nil {
 			return nil, errors.Wrap(err, `invalid value for "since"`)
@@ -53,7 +91,12 @@ func (cli *Client) ContainerLogs(ctx context.Context, container string, options
 		query.Set("since", ts)
 	}
 
-	if options.Until != "" {
+	switch {
+	case options.UntilDuration != 0:
+		query.Set("until", timetypes.Timestamp(time.Now().Add(-options.UntilDuration)))
+	case !options.UntilTime.IsZero():
+		query.Set("until", timetypes.Timestamp(options.UntilTime))
+	case options.Until != "":
 		ts, err := timetypes.GetTimestamp(options.Until, time.Now())
 		if err != nil {
 			return nil, errors.Wrap(err, `invalid value for "until"`)
@@ -74,51 +117,338 @@ func (cli *Client) ContainerLogs(ctx context.Context, container string, options
 	}
 	query.Set("tail", options.Tail)
 
+	var cancel context.CancelFunc
+	if options.InactivityTimeout > 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
 	resp, err := cli.get(ctx, "/containers/"+container+"/logs", query, nil)
 	if err != nil {
+		if cancel != nil {
+			cancel()
+		}
 		return nil, wrapResponseError(err, resp, "container", container)
 	}
+
+	if options.InactivityTimeout > 0 {
+		return newInactivityTimeoutReader(resp.body, cancel, options.InactivityTimeout), nil
+	}
 	return resp.body, nil
 }
 
-// containerLogsString returns container logs as a string.
+// ErrInactivityTimeout is returned from a read on a log stream created with
+// InactivityTimeout set, once no bytes have arrived for that duration.
+var ErrInactivityTimeout = errors.New("no log data received within the inactivity timeout")
+
+// inactivityTimeoutReader wraps a container logs body and aborts the read
+// with ErrInactivityTimeout if no bytes have been read for timeout, by
+// canceling the request context and closing the body out from under the
+// caller's in-flight Read.
+type inactivityTimeoutReader struct {
+	body     io.ReadCloser
+	cancel   context.CancelFunc
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut int32
+}
+
+func newInactivityTimeoutReader(body io.ReadCloser, cancel context.CancelFunc, timeout time.Duration) *inactivityTimeoutReader {
+	r := &inactivityTimeoutReader{body: body, cancel: cancel, timeout: timeout}
+	r.timer = time.AfterFunc(timeout, r.onTimeout)
+	return r
+}
+
+func (r *inactivityTimeoutReader) onTimeout() {
+	atomic.StoreInt32(&r.timedOut, 1)
+	r.cancel()
+	r.body.Close()
+}
+
+func (r *inactivityTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	if err != nil && atomic.LoadInt32(&r.timedOut) == 1 {
+		err = ErrInactivityTimeout
+	}
+	return n, err
+}
+
+func (r *inactivityTimeoutReader) Close() error {
+	r.timer.Stop()
+	r.cancel()
+	return r.body.Close()
+}
+
+// LogEntry is a single demultiplexed log line produced by ContainerLogsLines.
+type LogEntry struct {
+	Stream    stdcopy.StdType
+	Timestamp time.Time
+	Attrs     map[string]string
+	Message   string
+
+	// Reconnected is set on the synthetic entry ContainerLogsFollow passes
+	// to its handler right before resuming a dropped stream. Regular log
+	// entries never set it.
+	Reconnected bool
+}
+
+// ContainerLogsStreams returns the logs generated by a container as two
+// separate io.ReadClosers, one for stdout and one for stderr, along with a
+// cancel func that stops the underlying request.
 //
-// Regardless of whether the container carries the tty flag, it can convert the
-// container log stream into a string that does not contain garbled characters.
+// If the container is using a TTY, stdout carries the raw response body
+// unmodified and stderr is always empty, since the daemon does not multiplex
+// TTY output. Otherwise, the multiplexed stream described by ContainerLogs is
+// demultiplexed in a background goroutine as it arrives, buffering each
+// stream until it is read.
+//
+// It's up to the caller to close both streams; doing so unblocks the
+// background goroutine in the non-TTY case.
+func (cli *Client) ContainerLogsStreams(ctx context.Context, container string, options types.ContainerLogsOptions) (stdout, stderr io.ReadCloser, cancel func(), err error) {
+	ctx, cancel = context.WithCancel(ctx)
+
+	body, err := cli.containerLogsRaw(ctx, container, options)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+
+	containerInfo, err := cli.ContainerInspect(ctx, container)
+	if err != nil {
+		body.Close()
+		cancel()
+		return nil, nil, nil, err
+	}
+
+	if containerInfo.Config.Tty {
+		return body, io.NopCloser(bytes.NewReader(nil)), cancel, nil
+	}
+
+	stdout, stderr = demuxLogs(body)
+	return stdout, stderr, cancel, nil
+}
+
+// demuxLogs splits a multiplexed stdcopy stream into independent stdout and
+// stderr readers, on top of stdcopy.NewDemuxReader, adding the io.Closer
+// plumbing that API leaves out: closing either returned reader, or the
+// background goroutine hitting EOF/an error reading body on its own, closes
+// body exactly once.
+func demuxLogs(body io.ReadCloser) (stdout, stderr io.ReadCloser) {
+	var closeOnce sync.Once
+	closeBody := func() error {
+		var err error
+		closeOnce.Do(func() { err = body.Close() })
+		return err
+	}
+
+	outR, errR := stdcopy.NewDemuxReader(&closeOnReadErrorReader{ReadCloser: body, close: closeBody})
+
+	return &readCloser{Reader: outR, close: closeBody}, &readCloser{Reader: errR, close: closeBody}
+}
+
+// closeOnReadErrorReader closes the underlying body as soon as a Read from
+// it returns an error, so a clean EOF (or a connection drop) from the
+// stdcopy.NewDemuxReader goroutine closes body without anyone having to
+// consume both readers down to EOF first.
+type closeOnReadErrorReader struct {
+	io.ReadCloser
+	close func() error
+}
+
+func (r *closeOnReadErrorReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil {
+		r.close()
+	}
+	return n, err
+}
+
+// readCloser pairs a plain io.Reader with a shared close func, letting
+// demuxLogs hand out io.ReadClosers backed by stdcopy.NewDemuxReader's
+// io.Reader results.
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r *readCloser) Close() error { return r.close() }
+
+// ContainerLogsLines returns the logs generated by a container as a channel
+// of LogEntry, one per line, tagged with the stream it came from. When
+// options.Timestamps is set, each entry's Timestamp is parsed out of the
+// leading RFC3339Nano prefix the daemon adds to every line.
 //
-// containerLogsString is an encapsulation of containerLogs, but it can return
-// container logs of type string.
+// The returned channel is closed once both the stdout and stderr streams
+// have been fully consumed or the context is canceled.
+func (cli *Client) ContainerLogsLines(ctx context.Context, container string, options types.ContainerLogsOptions) (<-chan LogEntry, error) {
+	entries, errc, cancel, err := cli.containerLogsLines(ctx, container, options)
+	if err != nil {
+		return nil, err
+	}
+	// ContainerLogsLines doesn't surface stream errors to its caller; drain
+	// errc so the scanning goroutines never block trying to report one.
+	go func() {
+		<-errc
+		<-errc
+		cancel()
+	}()
+	return entries, nil
+}
+
+// containerLogsLines is the shared plumbing behind ContainerLogsLines and
+// ContainerLogsFollow: it merges the demultiplexed stdout/stderr streams of
+// a single ContainerLogsStreams call into one LogEntry channel, and reports
+// each stream's terminal error (nil on a clean EOF) on errc.
 //
-// If you want to distribute logs to stdout and stderr, please call ContainerLogs directly
-func (cli *Client) ContainerLogsString(ctx context.Context, container string, options types.ContainerLogsOptions) (string, error) {
-	containerInfo,err := cli.ContainerInspect(ctx,container)
-	if nil != err {
-		return "",err
+// The returned cancel must be called once the caller is done with entries,
+// even on an early exit: it's what lets scanLogStream's goroutines give up
+// on a send nobody is reading anymore instead of blocking forever.
+func (cli *Client) containerLogsLines(ctx context.Context, container string, options types.ContainerLogsOptions) (entries <-chan LogEntry, errc <-chan error, cancel func(), err error) {
+	ctx, cancel = context.WithCancel(ctx)
+
+	stdout, stderr, streamsCancel, err := cli.ContainerLogsStreams(ctx, container, options)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
 	}
 
-	content, err := cli.ContainerLogs(ctx, container,options)
-	if  nil != err{
-		return "",err
+	out := make(chan LogEntry)
+	errs := make(chan error, 2)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		scanLogStream(ctx, stdout, stdcopy.Stdout, options.Timestamps, options.Details, out, errs)
+	}()
+	go func() {
+		defer wg.Done()
+		scanLogStream(ctx, stderr, stdcopy.Stderr, options.Timestamps, options.Details, out, errs)
+	}()
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, errs, func() { cancel(); streamsCancel() }, nil
+}
+
+// scanLogStream reads newline-delimited LogEntry values out of r until EOF
+// or a read error, sending each to out and finally reporting scanner.Err()
+// (nil on a clean EOF) on errc. If ctx is canceled while a send to out is
+// blocked -- e.g. the caller gave up reading without draining the stream --
+// scanLogStream abandons the loop instead of leaking the goroutine.
+func scanLogStream(ctx context.Context, r io.ReadCloser, stream stdcopy.StdType, timestamps, details bool, out chan<- LogEntry, errc chan<- error) {
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		entry := parseLogLine(stream, timestamps, details, scanner.Text())
+		select {
+		case out <- entry:
+		case <-ctx.Done():
+			errc <- ctx.Err()
+			return
+		}
 	}
-	defer content.Close()
+	errc <- scanner.Err()
+}
 
-	stdOut := new(bytes.Buffer)
+// parseLogLine builds a LogEntry from a single demultiplexed log line,
+// splitting off the leading timestamp/details prefix the daemon adds when
+// hasTimestamp/hasDetails are set, the same prefix splitLogLinePrefix parses
+// for the JSON-lines format.
+func parseLogLine(stream stdcopy.StdType, hasTimestamp, hasDetails bool, line string) LogEntry {
+	rawTs, attrs, message := splitLogLinePrefix(line, hasTimestamp, hasDetails)
+	entry := LogEntry{Stream: stream, Attrs: attrs, Message: line}
 
-	// Handle container logs with tty flag
-	if containerInfo.Config.Tty{
-		_, err = io.Copy(stdOut,content)
+	if !hasTimestamp {
+		entry.Message = message
+		return entry
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, rawTs)
+	if err != nil {
+		return entry
+	}
+
+	entry.Timestamp = ts
+	entry.Message = message
+	return entry
+}
+
+// lockedWriter serializes writes from multiple goroutines into a shared
+// io.Writer, preserving the arrival order of whichever stream wrote first.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
+
+// ContainerLogsString returns container logs as a string.
+//
+// Regardless of whether the container carries the tty flag, it can convert the
+// container log stream into a string that does not contain garbled characters.
+//
+// ContainerLogsString is built on top of ContainerLogsStreams.
+//
+// If you want to distribute logs to stdout and stderr, please call
+// ContainerLogsStreams directly.
+//
+// If options.Format is ContainerLogsFormatJSONLines, the returned string is
+// the raw JSON-lines stream instead, as produced by ContainerLogs.
+func (cli *Client) ContainerLogsString(ctx context.Context, container string, options types.ContainerLogsOptions) (string, error) {
+	if options.Format == types.ContainerLogsFormatJSONLines {
+		body, err := cli.ContainerLogs(ctx, container, options)
 		if err != nil {
-			return "",err
+			return "", err
 		}
-	}else {
-		// Handle container logs without the tty flag
-		_, err = stdcopy.StdCopy(stdOut,stdOut, content)
-		if err != nil {
-			return "",err
+		defer body.Close()
+
+		buf := new(bytes.Buffer)
+		if _, err := io.Copy(buf, body); err != nil {
+			return "", err
 		}
+		return buf.String(), nil
 	}
 
-	return stdOut.String(),nil
-}
+	stdout, stderr, cancel, err := cli.ContainerLogsStreams(ctx, container, options)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	buf := new(bytes.Buffer)
+	lw := &lockedWriter{w: buf}
+
+	var wg sync.WaitGroup
+	var outErr, errErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer stdout.Close()
+		_, outErr = io.Copy(lw, stdout)
+	}()
+	go func() {
+		defer wg.Done()
+		defer stderr.Close()
+		_, errErr = io.Copy(lw, stderr)
+	}()
+	wg.Wait()
 
+	if outErr != nil {
+		return "", outErr
+	}
+	if errErr != nil {
+		return "", errErr
+	}
 
+	return buf.String(), nil
+}
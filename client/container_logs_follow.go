@@ -0,0 +1,108 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	followInitialBackoff = 500 * time.Millisecond
+	followMaxBackoff     = 30 * time.Second
+)
+
+// ContainerLogsFollow follows a container's logs, invoking handler once per
+// LogEntry. Timestamps is forced on internally, since the timestamp of the
+// last delivered entry is what makes a reconnect resumable.
+//
+// If the stream ends with an error while ctx is still live (a network error,
+// a daemon restart cutting the connection), it automatically reconnects with
+// exponential backoff, resuming
+// with Since set to the last successfully delivered entry's timestamp plus
+// 1ns so no line is lost or duplicated. Right before each reconnect, handler
+// receives a synthetic LogEntry with Reconnected set, instead of a callback,
+// so a single handler func covers both log lines and reconnect notices.
+//
+// ContainerLogsFollow returns when handler returns an error (which it then
+// returns unwrapped), ctx is canceled, or the log stream ends cleanly (e.g.
+// the container was removed). A clean EOF is not treated as a drop and is
+// never reconnected.
+func (cli *Client) ContainerLogsFollow(ctx context.Context, container string, options types.ContainerLogsOptions, handler func(LogEntry) error) error {
+	options.Follow = true
+	options.Timestamps = true
+
+	var lastTimestamp time.Time
+	var lastErr error
+	backoff := followInitialBackoff
+
+	for attempt := 0; ; attempt++ {
+		opts := options
+		if attempt > 0 {
+			opts.Since = ""
+			opts.SinceDuration = 0
+			opts.SinceTime = lastTimestamp.Add(time.Nanosecond)
+
+			if err := handler(LogEntry{Reconnected: true, Message: fmt.Sprintf("reconnecting after: %v", lastErr)}); err != nil {
+				return err
+			}
+		}
+
+		handlerErr, streamErr := cli.followOnce(ctx, container, opts, handler, &lastTimestamp)
+		if handlerErr != nil {
+			return handlerErr
+		}
+		if streamErr == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		lastErr = streamErr
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff = nextFollowBackoff(backoff)
+	}
+}
+
+// followOnce runs a single connect-and-stream attempt. handlerErr is set if
+// handler itself returned an error, in which case the caller must stop
+// retrying and propagate it. streamErr is the underlying cause of the
+// stream ending (nil on a clean EOF), which the caller uses to decide
+// whether to reconnect.
+func (cli *Client) followOnce(ctx context.Context, container string, options types.ContainerLogsOptions, handler func(LogEntry) error, lastTimestamp *time.Time) (handlerErr, streamErr error) {
+	entries, errc, cancel, err := cli.containerLogsLines(ctx, container, options)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	for entry := range entries {
+		if !entry.Timestamp.IsZero() {
+			*lastTimestamp = entry.Timestamp
+		}
+		if err := handler(entry); err != nil {
+			return err, nil
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := <-errc; err != nil && streamErr == nil {
+			streamErr = err
+		}
+	}
+	return nil, streamErr
+}
+
+func nextFollowBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > followMaxBackoff {
+		d = followMaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,106 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// buildLogFrame encodes a single stdcopy frame carrying a timestamped log
+// line, the wire format ContainerLogsFollow's underlying scanLogStream
+// expects when Timestamps is set.
+func buildLogFrame(stream stdcopy.StdType, line string) []byte {
+	header := make([]byte, 8)
+	header[0] = byte(stream)
+	binary.BigEndian.PutUint32(header[4:], uint32(len(line)))
+	return append(header, line...)
+}
+
+// dropAfterReader yields the wrapped bytes once, then err on every
+// subsequent Read, simulating a log stream that drops mid-flight.
+type dropAfterReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (r *dropAfterReader) Read(p []byte) (int, error) {
+	if r.r.Len() > 0 {
+		return r.r.Read(p)
+	}
+	return 0, r.err
+}
+
+func (r *dropAfterReader) Close() error { return nil }
+
+// TestContainerLogsFollowReconnectsAfterDrop drives ContainerLogsFollow
+// against a fake stream that drops once after delivering a line, and asserts
+// it reconnects (rather than returning the drop as a terminal error) and
+// keeps delivering entries until the retried stream ends cleanly.
+func TestContainerLogsFollowReconnectsAfterDrop(t *testing.T) {
+	dropErr := errors.New("connection reset by peer")
+	attempt := 0
+
+	httpClient := newMockClient(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/json") {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"Config":{"Tty":false}}`)),
+			}, nil
+		}
+
+		attempt++
+		if attempt == 1 {
+			body := buildLogFrame(stdcopy.Stdout, "2024-01-02T03:04:05.000000000Z hello\n")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/vnd.docker.multiplexed-stream"}},
+				Body:       &dropAfterReader{r: bytes.NewReader(body), err: dropErr},
+			}, nil
+		}
+
+		body := buildLogFrame(stdcopy.Stdout, "2024-01-02T03:04:06.000000000Z world\n")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/vnd.docker.multiplexed-stream"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	})
+
+	cli, err := NewClientWithOpts(WithHTTPClient(httpClient), WithHost("http://example.com"))
+	if err != nil {
+		t.Fatalf("NewClientWithOpts: %v", err)
+	}
+
+	var messages []string
+	var reconnected int
+	err = cli.ContainerLogsFollow(context.Background(), "container-id", types.ContainerLogsOptions{ShowStdout: true}, func(entry LogEntry) error {
+		if entry.Reconnected {
+			reconnected++
+			return nil
+		}
+		messages = append(messages, entry.Message)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ContainerLogsFollow: %v", err)
+	}
+
+	if want := []string{"hello", "world"}; len(messages) != len(want) || messages[0] != want[0] || messages[1] != want[1] {
+		t.Errorf("messages = %v, want %v", messages, want)
+	}
+	if reconnected != 1 {
+		t.Errorf("reconnect notices = %d, want 1", reconnected)
+	}
+	if attempt != 2 {
+		t.Errorf("connect attempts = %d, want 2", attempt)
+	}
+}
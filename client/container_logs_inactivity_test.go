@@ -0,0 +1,100 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+// blockingBody is an io.ReadCloser whose Read blocks until closed, so a test
+// can drive inactivityTimeoutReader's timeout path without a real slow
+// stream.
+type blockingBody struct {
+	closed chan struct{}
+}
+
+func newBlockingBody() *blockingBody {
+	return &blockingBody{closed: make(chan struct{})}
+}
+
+func (b *blockingBody) Read(p []byte) (int, error) {
+	<-b.closed
+	return 0, io.EOF
+}
+
+func (b *blockingBody) Close() error {
+	select {
+	case <-b.closed:
+	default:
+		close(b.closed)
+	}
+	return nil
+}
+
+func TestInactivityTimeoutReaderTimesOut(t *testing.T) {
+	body := newBlockingBody()
+	var canceled bool
+	cancel := func() { canceled = true }
+
+	r := newInactivityTimeoutReader(body, cancel, 10*time.Millisecond)
+
+	_, err := r.Read(make([]byte, 1))
+	if err != ErrInactivityTimeout {
+		t.Errorf("err = %v, want %v", err, ErrInactivityTimeout)
+	}
+	if !canceled {
+		t.Error("cancel was not called on timeout")
+	}
+}
+
+func TestInactivityTimeoutReaderResetsOnActivity(t *testing.T) {
+	pr, pw := io.Pipe()
+	var cancelCalls int
+	cancel := func() { cancelCalls++ }
+
+	r := newInactivityTimeoutReader(io.NopCloser(pr), cancel, 50*time.Millisecond)
+	defer r.Close()
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			time.Sleep(20 * time.Millisecond)
+			pw.Write([]byte("x"))
+		}
+		pw.Close()
+	}()
+
+	buf := make([]byte, 1)
+	for i := 0; i < 3; i++ {
+		if _, err := r.Read(buf); err != nil {
+			t.Fatalf("Read %d: %v", i, err)
+		}
+	}
+	if cancelCalls != 0 {
+		t.Errorf("cancel called %d times, want 0 -- reads arrived within the timeout", cancelCalls)
+	}
+
+	if _, err := r.Read(buf); err != io.EOF {
+		t.Errorf("final read err = %v, want io.EOF", err)
+	}
+}
+
+func TestInactivityTimeoutReaderCloseStopsTimer(t *testing.T) {
+	body := newBlockingBody()
+	var canceled bool
+	cancel := func() { canceled = true }
+
+	r := newInactivityTimeoutReader(body, cancel, 10*time.Millisecond)
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !canceled {
+		t.Error("cancel was not called by Close")
+	}
+
+	// Give a buggy timer a chance to fire after Close; onTimeout would flip
+	// timedOut and double-close body, which Close (via blockingBody) treats
+	// as a no-op, so the only observable symptom would be a data race under
+	// -race. Sleeping past the timeout here is the simplest way to exercise
+	// that window.
+	time.Sleep(20 * time.Millisecond)
+}
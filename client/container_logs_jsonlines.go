@@ -0,0 +1,150 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// jsonLogLine is the wire shape of a single ContainerLogsFormatJSONLines
+// entry.
+type jsonLogLine struct {
+	Stream  string            `json:"stream"`
+	Time    string            `json:"time,omitempty"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+	Message string            `json:"message"`
+}
+
+// containerLogsJSONLines converts the raw logs response body for container
+// into the ContainerLogsFormatJSONLines stream: it inspects the container to
+// tell a TTY single stream from a multiplexed one, demultiplexes in the
+// latter case, and merges stdout/stderr back into a single stream of
+// newline-delimited JSON objects.
+func (cli *Client) containerLogsJSONLines(ctx context.Context, container string, body io.ReadCloser, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	containerInfo, err := cli.ContainerInspect(ctx, container)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	if containerInfo.Config.Tty {
+		return newJSONLinesReader(body, stdcopy.Stdout, options), nil
+	}
+
+	stdout, stderr := demuxLogs(body)
+	return mergeReadClosers(
+		newJSONLinesReader(stdout, stdcopy.Stdout, options),
+		newJSONLinesReader(stderr, stdcopy.Stderr, options),
+	), nil
+}
+
+// newJSONLinesReader reads newline-delimited log lines out of r, tags each
+// with stream, splits out the Timestamps/Details prefix options asked for,
+// and returns a reader of the resulting JSON-encoded lines.
+func newJSONLinesReader(r io.ReadCloser, stream stdcopy.StdType, options types.ContainerLogsOptions) io.ReadCloser {
+	streamName := "stdout"
+	if stream == stdcopy.Stderr {
+		streamName = "stderr"
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer r.Close()
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			ts, attrs, message := splitLogLinePrefix(scanner.Text(), options.Timestamps, options.Details)
+			data, err := json.Marshal(jsonLogLine{Stream: streamName, Time: ts, Attrs: attrs, Message: message})
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			data = append(data, '\n')
+			if _, err := pw.Write(data); err != nil {
+				return
+			}
+		}
+		pw.CloseWithError(scanner.Err())
+	}()
+
+	return pr
+}
+
+// splitLogLinePrefix pulls the leading "<timestamp> <key=value,...> " prefix
+// the daemon adds to a log line when Timestamps/Details were requested,
+// returning whatever is left as message.
+func splitLogLinePrefix(line string, hasTimestamp, hasDetails bool) (ts string, attrs map[string]string, message string) {
+	rest := line
+
+	if hasTimestamp {
+		parts := strings.SplitN(rest, " ", 2)
+		ts = parts[0]
+		rest = ""
+		if len(parts) == 2 {
+			rest = parts[1]
+		}
+	}
+
+	if hasDetails {
+		parts := strings.SplitN(rest, " ", 2)
+		if len(parts) == 2 && strings.Contains(parts[0], "=") {
+			attrs = parseLogAttrs(parts[0])
+			rest = parts[1]
+		}
+	}
+
+	return ts, attrs, rest
+}
+
+// parseLogAttrs parses a comma-separated "key=value,key2=value2" string, as
+// produced by the daemon's --log-opt labels/env annotations, into a map.
+func parseLogAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			attrs[parts[0]] = parts[1]
+		}
+	}
+	return attrs
+}
+
+// mergeReadClosers merges readers into a single io.ReadCloser. io.Pipe
+// allows concurrent writers, so each reader is copied to the shared pipe
+// from its own goroutine without any extra locking. If any reader fails,
+// that error (rather than a clean io.EOF) is surfaced to the merged
+// reader's final Read once all goroutines have finished.
+func mergeReadClosers(readers ...io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	wg.Add(len(readers))
+	for _, r := range readers {
+		go func(r io.ReadCloser) {
+			defer wg.Done()
+			defer r.Close()
+			if _, err := io.Copy(pw, r); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(r)
+	}
+
+	go func() {
+		wg.Wait()
+		pw.CloseWithError(firstErr)
+	}()
+
+	return pr
+}
@@ -0,0 +1,76 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitLogLinePrefix(t *testing.T) {
+	cases := []struct {
+		name         string
+		line         string
+		hasTimestamp bool
+		hasDetails   bool
+		wantTime     string
+		wantAttrs    map[string]string
+		wantMessage  string
+	}{
+		{
+			name:        "no timestamp or details",
+			line:        "hello world",
+			wantMessage: "hello world",
+		},
+		{
+			name:         "timestamp only",
+			line:         "2024-01-02T03:04:05.000000000Z hello world",
+			hasTimestamp: true,
+			wantTime:     "2024-01-02T03:04:05.000000000Z",
+			wantMessage:  "hello world",
+		},
+		{
+			name:        "details only",
+			line:        "com.docker.a=1,com.docker.b=2 hello world",
+			hasDetails:  true,
+			wantAttrs:   map[string]string{"com.docker.a": "1", "com.docker.b": "2"},
+			wantMessage: "hello world",
+		},
+		{
+			name:         "timestamp and details",
+			line:         "2024-01-02T03:04:05.000000000Z com.docker.a=1 hello world",
+			hasTimestamp: true,
+			hasDetails:   true,
+			wantTime:     "2024-01-02T03:04:05.000000000Z",
+			wantAttrs:    map[string]string{"com.docker.a": "1"},
+			wantMessage:  "hello world",
+		},
+		{
+			name:        "details requested but absent",
+			line:        "hello world",
+			hasDetails:  true,
+			wantMessage: "hello world",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ts, attrs, message := splitLogLinePrefix(c.line, c.hasTimestamp, c.hasDetails)
+			if ts != c.wantTime {
+				t.Errorf("time = %q, want %q", ts, c.wantTime)
+			}
+			if !reflect.DeepEqual(attrs, c.wantAttrs) {
+				t.Errorf("attrs = %#v, want %#v", attrs, c.wantAttrs)
+			}
+			if message != c.wantMessage {
+				t.Errorf("message = %q, want %q", message, c.wantMessage)
+			}
+		})
+	}
+}
+
+func TestParseLogAttrs(t *testing.T) {
+	got := parseLogAttrs("a=1,b=2,c=")
+	want := map[string]string{"a": "1", "b": "2", "c": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLogAttrs = %#v, want %#v", got, want)
+	}
+}
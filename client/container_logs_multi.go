@@ -0,0 +1,156 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// TaggedLogLine is a single log line produced by MultiContainerLogs, tagged
+// with the container it came from. Err is set, with Entry left zero, on the
+// final value for a container whose stream ended because of an error (a
+// clean EOF sends no such value) -- e.g. a network blip or daemon restart --
+// so callers can tell that container dropping out from its logs simply
+// ending.
+type TaggedLogLine struct {
+	ContainerID string
+	Entry       LogEntry
+	Err         error
+}
+
+// MultiContainerLogs fans out one log stream per container in ids and
+// merges the results into a single channel. Lines are delivered in the
+// order they arrive from each container, not sorted by timestamp across
+// containers.
+//
+// The returned channel is closed once every container's logs have been
+// fully consumed or the context is canceled.
+func (cli *Client) MultiContainerLogs(ctx context.Context, ids []string, options types.ContainerLogsOptions) (<-chan TaggedLogLine, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	merged := make(chan TaggedLogLine)
+	var wg sync.WaitGroup
+	var opened []func() // cancel funcs for sources already opened, for cleanup on a later failure
+
+	for _, id := range ids {
+		entries, errc, srcCancel, err := cli.containerLogsLines(ctx, id, options)
+		if err != nil {
+			for _, srcCancel := range opened {
+				srcCancel()
+			}
+			cancel()
+			return nil, fmt.Errorf("getting logs for container %s: %w", id, err)
+		}
+		opened = append(opened, srcCancel)
+
+		// Start consuming this source immediately, interleaved with opening
+		// the rest -- waiting until every ContainerLogs call above has
+		// succeeded before starting any consumer would leave the sources
+		// opened so far with no reader if a later one failed, stalling
+		// their producer goroutines forever on an unconsumed send.
+		wg.Add(1)
+		go func(id string, entries <-chan LogEntry, errc <-chan error, cancel func()) {
+			defer wg.Done()
+			defer cancel()
+
+			for entry := range entries {
+				select {
+				case merged <- TaggedLogLine{ContainerID: id, Entry: entry}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			// entries closed: report the stream's terminal error, if any,
+			// so a dropped connection isn't indistinguishable from the
+			// container's logs ending cleanly.
+			for i := 0; i < 2; i++ {
+				if err := <-errc; err != nil {
+					select {
+					case merged <- TaggedLogLine{ContainerID: id, Err: err}:
+					case <-ctx.Done():
+					}
+					return
+				}
+			}
+		}(id, entries, errc, srcCancel)
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(merged)
+	}()
+
+	return merged, nil
+}
+
+// MultiContainerLogsTo writes the merged, source-tagged logs of ids to
+// stdout and stderr, formatting each line with a colored, aligned prefix
+// derived from the container's truncated ID -- the same shape as `docker
+// service logs` or `docker-compose logs` output, without requiring Swarm.
+// A container whose stream drops with an error gets a final prefixed error
+// line on stderr instead of silently going quiet.
+func (cli *Client) MultiContainerLogsTo(ctx context.Context, ids []string, options types.ContainerLogsOptions, stdout, stderr io.Writer) error {
+	lines, err := cli.MultiContainerLogs(ctx, ids, options)
+	if err != nil {
+		return err
+	}
+
+	prefixes := newLogPrefixer(ids)
+	for line := range lines {
+		if line.Err != nil {
+			fmt.Fprintf(stderr, "%s%v\n", prefixes.prefix(line.ContainerID), line.Err)
+			continue
+		}
+
+		w := stdout
+		if line.Entry.Stream == stdcopy.Stderr {
+			w = stderr
+		}
+		fmt.Fprintf(w, "%s%s\n", prefixes.prefix(line.ContainerID), line.Entry.Message)
+	}
+
+	return nil
+}
+
+// logPrefixColors cycles through a small palette of ANSI colors, one per
+// container, the same way docker-compose colors its per-service prefixes.
+var logPrefixColors = []string{"\033[36m", "\033[33m", "\033[32m", "\033[35m", "\033[34m", "\033[31m"}
+
+const logPrefixColorReset = "\033[0m"
+
+// logPrefixer builds the "<color><short-id><reset> | " prefix for each
+// container, padding short IDs to a common width so columns line up.
+type logPrefixer struct {
+	width int
+	color map[string]string
+}
+
+func newLogPrefixer(ids []string) *logPrefixer {
+	p := &logPrefixer{color: make(map[string]string, len(ids))}
+	for i, id := range ids {
+		short := shortID(id)
+		if len(short) > p.width {
+			p.width = len(short)
+		}
+		p.color[id] = logPrefixColors[i%len(logPrefixColors)]
+	}
+	return p
+}
+
+func (p *logPrefixer) prefix(id string) string {
+	short := shortID(id)
+	return fmt.Sprintf("%s%-*s%s | ", p.color[id], p.width, short, logPrefixColorReset)
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
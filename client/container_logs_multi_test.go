@@ -0,0 +1,127 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// TestMultiContainerLogsMergesSources drives MultiContainerLogs against two
+// containers and asserts lines from both show up on the merged channel,
+// tagged with the container they came from.
+func TestMultiContainerLogsMergesSources(t *testing.T) {
+	lines := map[string]string{
+		"container-a": "2024-01-02T03:04:05.000000000Z from a\n",
+		"container-b": "2024-01-02T03:04:05.000000000Z from b\n",
+	}
+
+	httpClient := newMockClient(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/json") {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"Config":{"Tty":false}}`)),
+			}, nil
+		}
+
+		for id, line := range lines {
+			if strings.Contains(req.URL.Path, id) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": []string{"application/vnd.docker.multiplexed-stream"}},
+					Body:       io.NopCloser(strings.NewReader(string(buildLogFrame(stdcopy.Stdout, line)))),
+				}, nil
+			}
+		}
+		return nil, errors.New("unexpected request: " + req.URL.Path)
+	})
+
+	cli, err := NewClientWithOpts(WithHTTPClient(httpClient), WithHost("http://example.com"))
+	if err != nil {
+		t.Fatalf("NewClientWithOpts: %v", err)
+	}
+
+	merged, err := cli.MultiContainerLogs(context.Background(), []string{"container-a", "container-b"}, types.ContainerLogsOptions{ShowStdout: true, Timestamps: true})
+	if err != nil {
+		t.Fatalf("MultiContainerLogs: %v", err)
+	}
+
+	got := map[string]string{}
+	for line := range merged {
+		if line.Err != nil {
+			t.Fatalf("unexpected TaggedLogLine.Err: %v", line.Err)
+		}
+		got[line.ContainerID] = line.Entry.Message
+	}
+
+	if got["container-a"] != "from a" || got["container-b"] != "from b" {
+		t.Errorf("got = %#v, want from a/from b for container-a/container-b", got)
+	}
+}
+
+// TestMultiContainerLogsCleansUpOnPartialFailure asserts that when opening a
+// later container's logs fails, the sources already opened are canceled
+// instead of left streaming with no consumer.
+func TestMultiContainerLogsCleansUpOnPartialFailure(t *testing.T) {
+	var sourceBodyClosed = make(chan struct{})
+
+	httpClient := newMockClient(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/json") {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"Config":{"Tty":false}}`)),
+			}, nil
+		}
+		if strings.Contains(req.URL.Path, "container-a") {
+			frame := buildLogFrame(stdcopy.Stdout, "2024-01-02T03:04:05.000000000Z from a\n")
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/vnd.docker.multiplexed-stream"}},
+				Body:       &closeSignalingBody{r: strings.NewReader(string(frame)), closed: sourceBodyClosed},
+			}, nil
+		}
+		return nil, errors.New("boom: container-b unavailable")
+	})
+
+	cli, err := NewClientWithOpts(WithHTTPClient(httpClient), WithHost("http://example.com"))
+	if err != nil {
+		t.Fatalf("NewClientWithOpts: %v", err)
+	}
+
+	_, err = cli.MultiContainerLogs(context.Background(), []string{"container-a", "container-b"}, types.ContainerLogsOptions{ShowStdout: true})
+	if err == nil {
+		t.Fatal("MultiContainerLogs: want error when one of several containers fails to open, got nil")
+	}
+
+	select {
+	case <-sourceBodyClosed:
+	case <-time.After(time.Second):
+		t.Error("container-a's body was not closed after container-b failed to open")
+	}
+}
+
+// closeSignalingBody closes a channel the first time Close is called, so a
+// test can observe that a partially-opened source's cleanup ran.
+type closeSignalingBody struct {
+	r      *strings.Reader
+	closed chan struct{}
+}
+
+func (b *closeSignalingBody) Read(p []byte) (int, error) { return b.r.Read(p) }
+
+func (b *closeSignalingBody) Close() error {
+	select {
+	case <-b.closed:
+	default:
+		close(b.closed)
+	}
+	return nil
+}
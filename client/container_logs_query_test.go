@@ -0,0 +1,110 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	timetypes "github.com/docker/docker/api/types/time"
+)
+
+// TestContainerLogsSinceUntilPrecedence asserts the precedence rule
+// documented on ContainerLogsOptions: SinceDuration/UntilDuration win over
+// SinceTime/UntilTime, which in turn win over the raw Since/Until strings.
+func TestContainerLogsSinceUntilPrecedence(t *testing.T) {
+	fixedTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	fixedDuration := 2 * time.Hour
+
+	cases := []struct {
+		name      string
+		options   types.ContainerLogsOptions
+		wantSince string
+		wantUntil string
+	}{
+		{
+			name: "since/until string only",
+			options: types.ContainerLogsOptions{
+				Since: "2024-01-02T03:04:05.000000000Z",
+				Until: "2024-01-02T04:04:05.000000000Z",
+			},
+			wantSince: "1704164645.000000000",
+			wantUntil: "1704168245.000000000",
+		},
+		{
+			name: "SinceTime/UntilTime win over Since/Until strings",
+			options: types.ContainerLogsOptions{
+				Since:     "2024-01-02T03:04:05.000000000Z",
+				Until:     "2024-01-02T04:04:05.000000000Z",
+				SinceTime: fixedTime,
+				UntilTime: fixedTime,
+			},
+			wantSince: "1704164645.000000000",
+			wantUntil: "1704164645.000000000",
+		},
+		{
+			name: "SinceDuration/UntilDuration win over SinceTime/UntilTime",
+			options: types.ContainerLogsOptions{
+				SinceTime:     fixedTime,
+				UntilTime:     fixedTime,
+				SinceDuration: fixedDuration,
+				UntilDuration: fixedDuration,
+			},
+			wantSince: "",
+			wantUntil: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var gotQuery map[string][]string
+
+			httpClient := newMockClient(func(req *http.Request) (*http.Response, error) {
+				gotQuery = map[string][]string(req.URL.Query())
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("")),
+					Header:     http.Header{"Content-Type": []string{"text/plain"}},
+				}, nil
+			})
+
+			cli, err := NewClientWithOpts(WithHTTPClient(httpClient), WithHost("http://example.com"))
+			if err != nil {
+				t.Fatalf("NewClientWithOpts: %v", err)
+			}
+
+			body, err := cli.ContainerLogs(context.Background(), "container-id", c.options)
+			if err != nil {
+				t.Fatalf("ContainerLogs: %v", err)
+			}
+			defer body.Close()
+
+			// SinceDuration/UntilDuration are relative to time.Now(), so just
+			// assert the precedence rule picked the duration-derived query
+			// param over the fixed SinceTime/UntilTime value, rather than
+			// asserting an exact timestamp.
+			if c.wantSince == "" {
+				if got := gotQuery["since"][0]; got == timestampOf(fixedTime) {
+					t.Errorf("since = %q, want a SinceDuration-derived value, not SinceTime's", got)
+				}
+			} else if got := gotQuery["since"]; len(got) != 1 || got[0] != c.wantSince {
+				t.Errorf("since = %v, want [%q]", got, c.wantSince)
+			}
+
+			if c.wantUntil == "" {
+				if got := gotQuery["until"][0]; got == timestampOf(fixedTime) {
+					t.Errorf("until = %q, want an UntilDuration-derived value, not UntilTime's", got)
+				}
+			} else if got := gotQuery["until"]; len(got) != 1 || got[0] != c.wantUntil {
+				t.Errorf("until = %v, want [%q]", got, c.wantUntil)
+			}
+		})
+	}
+}
+
+func timestampOf(t time.Time) string {
+	return timetypes.Timestamp(t)
+}
@@ -0,0 +1,64 @@
+package client // import "github.com/docker/docker/client"
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+func TestParseLogLineWithoutTimestamp(t *testing.T) {
+	entry := parseLogLine(stdcopy.Stdout, false, false, "2024-01-02T03:04:05.000000000Z hello world")
+
+	if entry.Stream != stdcopy.Stdout {
+		t.Errorf("Stream = %v, want %v", entry.Stream, stdcopy.Stdout)
+	}
+	if !entry.Timestamp.IsZero() {
+		t.Errorf("Timestamp = %v, want zero value", entry.Timestamp)
+	}
+	if entry.Message != "2024-01-02T03:04:05.000000000Z hello world" {
+		t.Errorf("Message = %q, want the raw line unchanged", entry.Message)
+	}
+}
+
+func TestParseLogLineWithTimestamp(t *testing.T) {
+	entry := parseLogLine(stdcopy.Stderr, true, false, "2024-01-02T03:04:05.000000000Z hello world")
+
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !entry.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", entry.Timestamp, want)
+	}
+	if entry.Message != "hello world" {
+		t.Errorf("Message = %q, want %q", entry.Message, "hello world")
+	}
+	if entry.Stream != stdcopy.Stderr {
+		t.Errorf("Stream = %v, want %v", entry.Stream, stdcopy.Stderr)
+	}
+}
+
+func TestParseLogLineWithTimestampButUnparseablePrefix(t *testing.T) {
+	entry := parseLogLine(stdcopy.Stdout, true, false, "not-a-timestamp hello world")
+
+	if !entry.Timestamp.IsZero() {
+		t.Errorf("Timestamp = %v, want zero value for an unparseable prefix", entry.Timestamp)
+	}
+	if entry.Message != "not-a-timestamp hello world" {
+		t.Errorf("Message = %q, want the raw line left untouched", entry.Message)
+	}
+}
+
+func TestParseLogLineWithTimestampAndDetails(t *testing.T) {
+	entry := parseLogLine(stdcopy.Stdout, true, true, "2024-01-02T03:04:05.000000000Z com.docker.a=1 hello world")
+
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !entry.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", entry.Timestamp, want)
+	}
+	if want := map[string]string{"com.docker.a": "1"}; !reflect.DeepEqual(entry.Attrs, want) {
+		t.Errorf("Attrs = %#v, want %#v", entry.Attrs, want)
+	}
+	if entry.Message != "hello world" {
+		t.Errorf("Message = %q, want %q", entry.Message, "hello world")
+	}
+}
@@ -0,0 +1,229 @@
+package stdcopy // import "github.com/docker/docker/pkg/stdcopy"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// StdType is the type of standard stream
+// a writer can multiplex to.
+type StdType byte
+
+const (
+	// Stdin represents standard input stream type.
+	Stdin StdType = iota
+	// Stdout represents standard output stream type.
+	Stdout
+	// Stderr represents standard error stream type.
+	Stderr
+
+	stdWriterPrefixLen = 8
+	stdWriterFdIndex   = 0
+	stdWriterSizeIndex = 4
+
+	startingBufLen = 32*1024 + stdWriterPrefixLen + 1
+)
+
+// ReadHeader parses an 8-byte stdcopy frame header, as produced by
+// StdWriter and read by StdCopy, into the stream it identifies and the
+// size of the payload that follows it. Callers that want to demultiplex a
+// stream themselves (e.g. into two io.Readers instead of two io.Writers)
+// can reuse this instead of reimplementing the header layout.
+func ReadHeader(header []byte) (stream StdType, frameSize int) {
+	stream = StdType(header[stdWriterFdIndex])
+	frameSize = int(binary.BigEndian.Uint32(header[stdWriterSizeIndex : stdWriterSizeIndex+4]))
+	return stream, frameSize
+}
+
+// NewDemuxReader starts a background goroutine that parses the stdcopy
+// frames read from r and returns independent stdout and stderr readers fed
+// from it, so callers can plug multiplexed container output into anything
+// that consumes an io.Reader -- bufio.Scanner, json.Decoder, a gRPC stream
+// -- instead of writing the io.Pipe-plus-goroutine dance StdCopy's
+// writer-based API forces on them.
+//
+// The two readers are backed by their own internal buffer rather than an
+// io.Pipe, so the background goroutine never blocks waiting for one stream
+// to be read: a caller can drain stdout to EOF before ever touching stderr
+// (or vice versa) without deadlocking. Once r is exhausted or a read from it
+// fails, both readers return that error (io.EOF on a clean end) once their
+// buffered data is drained.
+func NewDemuxReader(r io.Reader) (stdout, stderr io.Reader) {
+	outB := newDemuxBuffer()
+	errB := newDemuxBuffer()
+
+	go func() {
+		header := make([]byte, stdWriterPrefixLen)
+		for {
+			if _, err := io.ReadFull(r, header); err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				outB.CloseWithError(err)
+				errB.CloseWithError(err)
+				return
+			}
+
+			stream, frameSize := ReadHeader(header)
+			b := outB
+			if stream == Stderr {
+				b = errB
+			}
+
+			if _, err := io.CopyN(b, r, int64(frameSize)); err != nil {
+				outB.CloseWithError(err)
+				errB.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return outB, errB
+}
+
+// demuxBuffer is an io.Reader fed by NewDemuxReader's background goroutine. It
+// behaves like an io.Pipe that never blocks on Write: writes append to an
+// internal buffer and Read blocks only until there is something in it to
+// return, so the producer can run arbitrarily far ahead of a reader that
+// isn't being drained yet (e.g. because a caller is fully consuming the
+// other stream first).
+type demuxBuffer struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  bytes.Buffer
+	err  error
+}
+
+func newDemuxBuffer() *demuxBuffer {
+	b := &demuxBuffer{}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// Write appends p to the buffer. It never blocks.
+func (b *demuxBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n, err := b.buf.Write(p)
+	b.cond.Broadcast()
+	return n, err
+}
+
+// Read blocks until there is buffered data to return or the buffer has been
+// closed.
+func (b *demuxBuffer) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.buf.Len() == 0 && b.err == nil {
+		b.cond.Wait()
+	}
+	if b.buf.Len() > 0 {
+		return b.buf.Read(p)
+	}
+	return 0, b.err
+}
+
+// CloseWithError marks the buffer closed, causing subsequent reads of
+// already-buffered data to succeed and reads past that to return err (or
+// io.EOF if err is nil).
+func (b *demuxBuffer) CloseWithError(err error) {
+	if err == nil {
+		err = io.EOF
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err == nil {
+		b.err = err
+	}
+	b.cond.Broadcast()
+}
+
+// StdCopy is a modified version of io.Copy.
+//
+// StdCopy will demultiplex `src`, assuming that it contains two streams,
+// previously multiplexed together using a StdWriter instance.
+// As it reads from `src`, StdCopy will write to `dstout` and `dsterr`.
+//
+// StdCopy will read until it hits EOF on `src`. It will then return a nil error.
+// In other words: if `err` is non nil, it indicates a real underlying error.
+//
+// `written` will hold the total number of bytes written to `dstout` and `dsterr`.
+func StdCopy(dstout, dsterr io.Writer, src io.Reader) (written int64, err error) {
+	buf := make([]byte, startingBufLen)
+	bufLen := len(buf)
+	nr, nw := 0, 0
+	for {
+		// Make sure we have at least a full header
+		for nr < stdWriterPrefixLen {
+			var nr2 int
+			nr2, err = src.Read(buf[nr:])
+			if err != nil {
+				if err == io.EOF {
+					if nr < stdWriterPrefixLen {
+						return written, nil
+					}
+					break
+				}
+				return written, err
+			}
+			nr += nr2
+		}
+
+		stream := StdType(buf[stdWriterFdIndex])
+		// Check the first byte to know where to write
+		var out io.Writer
+		switch stream {
+		case Stdin, Stdout:
+			out = dstout
+		case Stderr:
+			out = dsterr
+		default:
+			return written, errors.New("unrecognized input header: " + string(rune(stream)))
+		}
+
+		// Retrieve the size of the frame
+		frameSize := int(binary.BigEndian.Uint32(buf[stdWriterSizeIndex : stdWriterSizeIndex+4]))
+
+		// Check if the buffer is big enough to read the frame.
+		if frameSize+stdWriterPrefixLen > bufLen {
+			buf = append(buf, make([]byte, frameSize+stdWriterPrefixLen-bufLen+1)...)
+			bufLen = len(buf)
+		}
+
+		// While the amount of bytes read is less than the size of the frame + header, we keep reading
+		for nr < frameSize+stdWriterPrefixLen {
+			var nr2 int
+			nr2, err = src.Read(buf[nr:])
+			if err != nil {
+				if err == io.EOF {
+					if nr < frameSize+stdWriterPrefixLen {
+						return written, nil
+					}
+					break
+				}
+				return written, err
+			}
+			nr += nr2
+		}
+
+		// Write the retrieved frame (without header)
+		nw, err = out.Write(buf[stdWriterPrefixLen : frameSize+stdWriterPrefixLen])
+		if err != nil {
+			return written, err
+		}
+
+		// If the frame has not been fully written: error
+		if nw != frameSize {
+			return written, io.ErrShortWrite
+		}
+		written += int64(nw)
+
+		// Move the rest of the buffer to the beginning
+		copy(buf, buf[frameSize+stdWriterPrefixLen:nr])
+		// Move the index
+		nr -= frameSize + stdWriterPrefixLen
+	}
+}
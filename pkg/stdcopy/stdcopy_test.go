@@ -0,0 +1,84 @@
+package stdcopy // import "github.com/docker/docker/pkg/stdcopy"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// frame encodes a single stdcopy frame for stream carrying payload.
+func frame(stream StdType, payload string) []byte {
+	header := make([]byte, stdWriterPrefixLen)
+	header[stdWriterFdIndex] = byte(stream)
+	binary.BigEndian.PutUint32(header[stdWriterSizeIndex:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestNewDemuxReader(t *testing.T) {
+	var src bytes.Buffer
+	src.Write(frame(Stdout, "hello "))
+	src.Write(frame(Stderr, "oops\n"))
+	src.Write(frame(Stdout, "world\n"))
+
+	stdout, stderr := NewDemuxReader(&src)
+
+	gotOut, err := io.ReadAll(stdout)
+	if err != nil {
+		t.Fatalf("reading stdout: %v", err)
+	}
+	if string(gotOut) != "hello world\n" {
+		t.Errorf("stdout = %q, want %q", gotOut, "hello world\n")
+	}
+
+	gotErr, err := io.ReadAll(stderr)
+	if err != nil {
+		t.Fatalf("reading stderr: %v", err)
+	}
+	if string(gotErr) != "oops\n" {
+		t.Errorf("stderr = %q, want %q", gotErr, "oops\n")
+	}
+}
+
+func TestNewDemuxReaderSurfacesReadError(t *testing.T) {
+	boom := io.ErrUnexpectedEOF
+	r := &errAfterReader{data: frame(Stdout, "partial"), err: boom}
+
+	stdout, stderr := NewDemuxReader(r)
+
+	if _, err := io.ReadAll(stdout); err != boom {
+		t.Errorf("stdout error = %v, want %v", err, boom)
+	}
+	if _, err := io.ReadAll(stderr); err != boom {
+		t.Errorf("stderr error = %v, want %v", err, boom)
+	}
+}
+
+// errAfterReader returns data once, then err on every subsequent Read.
+type errAfterReader struct {
+	data []byte
+	err  error
+	read bool
+}
+
+func (r *errAfterReader) Read(p []byte) (int, error) {
+	if !r.read {
+		r.read = true
+		return copy(p, r.data), nil
+	}
+	return 0, r.err
+}
+
+func TestReadHeader(t *testing.T) {
+	header := make([]byte, stdWriterPrefixLen)
+	header[stdWriterFdIndex] = byte(Stderr)
+	binary.BigEndian.PutUint32(header[stdWriterSizeIndex:], 42)
+
+	stream, size := ReadHeader(header)
+	if stream != Stderr {
+		t.Errorf("stream = %v, want %v", stream, Stderr)
+	}
+	if size != 42 {
+		t.Errorf("size = %d, want 42", size)
+	}
+}